@@ -96,6 +96,10 @@ void SerialComms::processMessage(uint8_t msgType, const uint8_t* payload, uint16
     case MSG_PING:
         break;  // keepalive — timestamp already updated above
 
+    case MSG_GET_VERSION:
+        sendFrame(MSG_VERSION, (const uint8_t*)FIRMWARE_VERSION, strlen(FIRMWARE_VERSION));
+        break;
+
     case MSG_SET_LABELS: {
         if (_onSetLabels && len > 0) {
             const char* labels[4] = {"", "", "", ""};
@@ -83,6 +83,10 @@
 #define MSG_SET_LABELS 0x06
 #define MSG_HEARTBEAT 0x07
 #define MSG_PING      0x08  // Host→Device: keepalive (no payload)
+#define MSG_GET_VERSION 0x09  // Host→Device: request firmware version (no payload)
+#define MSG_VERSION   0x0A  // Device→Host: firmware version (UTF-8 payload)
+
+#define FIRMWARE_VERSION "0.1.0"
 
 #define FRAME_START_BYTE 0xAA
 #define MAX_MSG_LEN 512
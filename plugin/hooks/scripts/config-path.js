@@ -17,7 +17,7 @@ function getConfigPath() {
   } else if (process.platform === 'win32') {
     base = path.join(process.env.APPDATA || path.join(os.homedir(), 'AppData', 'Roaming'), 'camel-pad');
   } else {
-    base = path.join(os.homedir(), '.config', 'camel-pad');
+    base = path.join(process.env.XDG_CONFIG_HOME || path.join(os.homedir(), '.config'), 'camel-pad');
   }
 
   // Create directory if it doesn't exist
@@ -0,0 +1,259 @@
+import { describe, test, expect, mock, beforeEach, afterEach } from 'bun:test';
+import { EventEmitter } from 'events';
+import { withDefaults } from './config/loader.js';
+import { installFakeClock, type FakeClock } from './testing/fake-clock.js';
+import type { BridgeHandle, BridgeOptions } from './bridge.js';
+import type { Config } from './types.js';
+
+// Stand-ins for the serial port, config file watcher and WebSocket listener,
+// so the bridge's wiring can be driven from a test. mock.module replaces a
+// module for the whole test run, not just this file (server.test loads `ws`,
+// for one), so each replacement constructs the real class unless a bridge
+// test is running.
+
+let faking = false;
+
+function whileFaking<T extends new (...args: any[]) => object>(Real: T, Fake: new () => object): T {
+  return function (...args: any[]) {
+    return faking ? new Fake() : new Real(...args);
+  } as unknown as T;
+}
+
+class FakeSerialDevice extends EventEmitter {
+  static instance: FakeSerialDevice;
+  calls: string[] = []; // Port operations, in order
+  labels: string[][] = [];
+  statuses: string[] = [];
+
+  constructor() {
+    super();
+    FakeSerialDevice.instance = this;
+  }
+
+  async connect() { this.calls.push('connect'); return true; }
+  disconnect() { this.calls.push('disconnect'); }
+  getInfo() { return { path: '/dev/cu.usbmodemTEST' }; }
+  sendLabels(labels: string[]) { this.calls.push('sendLabels'); this.labels.push(labels); return true; }
+  sendStatus(text: string) { this.calls.push('sendStatus'); this.statuses.push(text); return true; }
+  sendText() { this.calls.push('sendText'); return true; }
+  clearDisplay() { this.calls.push('clearDisplay'); return true; }
+  sendLeds() { this.calls.push('sendLeds'); return true; }
+  sendPing() { this.calls.push('sendPing'); return true; }
+  requestVersion() { this.calls.push('requestVersion'); return true; }
+  setDumpRaw() {}
+}
+
+class FakeConfigWatcher extends EventEmitter {
+  static instance: FakeConfigWatcher;
+  static config: Config;
+
+  constructor() {
+    super();
+    FakeConfigWatcher.instance = this;
+  }
+
+  getConfig() { return FakeConfigWatcher.config; }
+  start() {}
+  stop() {}
+}
+
+class FakeWebSocketServer extends EventEmitter {
+  close() {}
+}
+
+// Taken before mocking, in case the mock updates these namespaces in place
+const realDevice = { ...(await import('./serial/device.js')) };
+const realWatcher = { ...(await import('./config/watcher.js')) };
+const realWs = { ...(await import('ws')) };
+
+mock.module('./serial/device.js', () => ({
+  ...realDevice,
+  SerialDevice: whileFaking(realDevice.SerialDevice, FakeSerialDevice),
+}));
+mock.module('./config/watcher.js', () => ({
+  ...realWatcher,
+  ConfigWatcher: whileFaking(realWatcher.ConfigWatcher, FakeConfigWatcher),
+}));
+mock.module('ws', () => ({
+  ...realWs,
+  WebSocketServer: whileFaking(realWs.WebSocketServer, FakeWebSocketServer),
+}));
+
+const { startBridge } = await import('./bridge.js');
+
+const BASE: Partial<Config> = {
+  device: { port: '/dev/cu.usbmodemTEST' },
+  handedness: 'left', // physical index == logical index
+  keys: {
+    key0: { press: { action: 'approve', label: 'Yes' } },
+    key1: { press: { action: 'deny', label: 'No' } },
+  },
+};
+
+let clock: FakeClock;
+let bridge: BridgeHandle | null;
+
+async function start(overrides: Partial<Config> = {}, options: BridgeOptions = {}) {
+  FakeConfigWatcher.config = withDefaults({ ...BASE, ...overrides });
+  bridge = await startBridge('/tmp/camel-pad-test.yaml', options);
+  return {
+    bridge,
+    serial: FakeSerialDevice.instance,
+    watcher: FakeConfigWatcher.instance,
+  };
+}
+
+/** Gestures the bridge passed on to the notification server, from its log. */
+function gestures(): string[] {
+  return bridge!.getLogs().entries.filter(e => e.type === 'gesture').map(e => e.summary);
+}
+
+function tap(serial: FakeSerialDevice, buttonId: string) {
+  serial.emit('button', { buttonId, pressed: true });
+  serial.emit('button', { buttonId, pressed: false });
+}
+
+beforeEach(() => {
+  faking = true;
+  clock = installFakeClock();
+  bridge = null;
+});
+
+afterEach(() => {
+  bridge?.shutdown();
+  clock.restore();
+  faking = false;
+});
+
+describe('labels', () => {
+  test('a disabled press gives way to an enabled doublePress label', async () => {
+    const { serial } = await start({
+      keys: {
+        key0: {
+          press: { action: 'approve', label: 'Yes', enabled: false },
+          doublePress: { action: 'approve-all', label: 'Always' },
+        },
+      },
+    });
+    serial.emit('connected');
+    expect(serial.labels).toEqual([['Always', '', '', '']]);
+  });
+});
+
+describe('disconnect', () => {
+  test('abandons a gesture in progress', async () => {
+    const { serial } = await start();
+    serial.emit('connected');
+
+    serial.emit('button', { buttonId: 'key0', pressed: true });
+    serial.emit('disconnected');
+    clock.advance(1000); // past longPressMs
+    expect(gestures()).toEqual([]);
+
+    serial.emit('connected');
+    tap(serial, 'key0');
+    clock.advance(300);
+    expect(gestures()).toEqual(['key0 press']);
+  });
+});
+
+describe('input pause', () => {
+  test('a bridge started paused stays paused until resumed', async () => {
+    const { bridge, serial } = await start({}, { inputPaused: true });
+    serial.emit('connected');
+    expect(bridge.getStatus().inputPaused).toBe(true);
+    expect(serial.statuses).toEqual(['Input paused']);
+
+    tap(serial, 'key0');
+    clock.advance(300);
+    expect(gestures()).toEqual([]);
+
+    bridge.setInputPaused(false);
+    tap(serial, 'key0');
+    clock.advance(300);
+    expect(gestures()).toEqual(['key0 press']);
+    expect(serial.statuses).toEqual(['Input paused', 'Connected']);
+  });
+});
+
+describe('reconnect', () => {
+  test('reports the drop and restores status and current labels', async () => {
+    const { bridge, serial, watcher } = await start();
+    const states: boolean[] = [];
+    bridge.onStatusChange(status => states.push(status.connected));
+
+    serial.emit('connected');
+    serial.emit('disconnected');
+    expect(bridge.getStatus()).toMatchObject({ connected: false, portPath: null, firmwareVersion: null });
+
+    // Reloaded while unplugged; the replug must not bring back the old labels
+    watcher.emit('reload', withDefaults({
+      ...BASE,
+      keys: { key0: { press: { action: 'approve', label: 'OK' } } },
+    }));
+    expect(serial.labels).toEqual([['Yes', 'No', '', '']]);
+
+    serial.emit('connected');
+    expect(states).toEqual([true, false, true]);
+    expect(bridge.getStatus()).toMatchObject({ connected: true, portPath: '/dev/cu.usbmodemTEST' });
+    expect(serial.statuses).toEqual(['Connected', 'Connected']);
+    expect(serial.labels).toEqual([['Yes', 'No', '', ''], ['OK', '', '', '']]);
+  });
+});
+
+describe('ignoreButtons', () => {
+  test('ignored keys are blank and silent until a reload frees them', async () => {
+    const { serial, watcher } = await start({
+      device: { port: '/dev/cu.usbmodemTEST', ignoreButtons: [1] },
+    });
+    serial.emit('connected');
+    expect(serial.labels).toEqual([['Yes', '', '', '']]);
+
+    tap(serial, 'key1');
+    clock.advance(300);
+    expect(gestures()).toEqual([]);
+
+    watcher.emit('reload', withDefaults(BASE));
+    expect(serial.labels.at(-1)).toEqual(['Yes', 'No', '', '']);
+    tap(serial, 'key1');
+    clock.advance(300);
+    expect(gestures()).toEqual(['key1 press']);
+  });
+});
+
+describe('firmware version', () => {
+  test('is requested on connect and reported until the port drops', async () => {
+    const { bridge, serial } = await start();
+    serial.emit('connected');
+    expect(serial.calls).toEqual(['connect', 'sendStatus', 'requestVersion', 'sendLabels']);
+
+    serial.emit('version', '0.1.0');
+    expect(bridge.getStatus().firmwareVersion).toBe('0.1.0');
+    expect(bridge.getLogs().entries.filter(e => e.type === 'version')).toMatchObject([
+      { dir: 'in', summary: 'Firmware 0.1.0' },
+    ]);
+
+    serial.emit('disconnected');
+    expect(bridge.getStatus().firmwareVersion).toBeNull();
+  });
+});
+
+describe('shutdown', () => {
+  test('blanks the screen before closing the port', async () => {
+    const { bridge, serial } = await start();
+    serial.emit('connected');
+    serial.calls = [];
+
+    bridge.shutdown();
+    expect(serial.calls).toEqual(['clearDisplay', 'sendStatus', 'disconnect']);
+    expect(serial.statuses.at(-1)).toBe('Disconnected');
+  });
+
+  test('writes nothing when the pad is not connected', async () => {
+    const { bridge, serial } = await start();
+    serial.calls = [];
+
+    bridge.shutdown();
+    expect(serial.calls).toEqual(['disconnect']);
+  });
+});
@@ -3,12 +3,14 @@ import { GestureDetector } from './gesture/detector.js';
 import { ConfigWatcher } from './config/watcher.js';
 import { NotificationServer } from './websocket/server.js';
 import { validateConfig } from './config/loader.js';
-import type { NotificationMessage, LogEntry } from './types.js';
+import type { Config, NotificationMessage, LogEntry } from './types.js';
 
 export interface BridgeStatus {
   connected: boolean;
   portPath: string | null;
   pendingCount: number;
+  inputPaused: boolean;
+  firmwareVersion: string | null; // null until the device answers (older firmware never does)
 }
 
 export interface BridgeHandle {
@@ -21,13 +23,18 @@ export interface BridgeHandle {
   clearDisplay(): boolean;
   sendLeds(leds: Array<{ index: number; r: number; g: number; b: number }>): boolean;
   sendLabels(labels: string[]): boolean;
+  setInputPaused(paused: boolean): void;
+}
+
+export interface BridgeOptions {
+  inputPaused?: boolean; // Start with input paused, e.g. when restarting after a settings save
 }
 
 function remapButtonIndex(i: number, h: 'left' | 'right'): number {
   return h === 'right' ? 3 - i : i;
 }
 
-function extractLabelsForDisplay(config: any, handedness: 'left' | 'right'): string[] {
+function extractLabelsForDisplay(config: Config, handedness: 'left' | 'right'): string[] {
   // Extract labels for each logical key (key0-key3)
   const keyLabels: string[] = [];
 
@@ -35,10 +42,18 @@ function extractLabelsForDisplay(config: any, handedness: 'left' | 'right'): str
     const keyId = `key${i}`;
     const keyMapping = config.keys[keyId];
 
-    // Try to get label from press, then doublePress, then longPress
-    const label = keyMapping?.press?.label
-      || keyMapping?.doublePress?.label
-      || keyMapping?.longPress?.label
+    if (config.device.ignoreButtons?.includes(i)) {
+      keyLabels.push('');
+      continue;
+    }
+
+    // Try to get label from press, then doublePress, then longPress,
+    // skipping mappings and gesture types that have been disabled
+    const label = [
+      keyMapping?.press,
+      config.gestures.doublePress ? keyMapping?.doublePress : undefined,
+      config.gestures.longPress ? keyMapping?.longPress : undefined,
+    ].find(m => m?.label && m.enabled !== false)?.label
       || '';
 
     keyLabels.push(label);
@@ -57,7 +72,7 @@ function extractLabelsForDisplay(config: any, handedness: 'left' | 'right'): str
   return physicalLabels;
 }
 
-export async function startBridge(configPath: string): Promise<BridgeHandle> {
+export async function startBridge(configPath: string, options: BridgeOptions = {}): Promise<BridgeHandle> {
   const configWatcher = new ConfigWatcher(configPath);
   const config = configWatcher.getConfig();
 
@@ -70,20 +85,23 @@ export async function startBridge(configPath: string): Promise<BridgeHandle> {
     port: config.device.port,
     vendorId: config.device.vendorId,
     productId: config.device.productId,
+    serialNumber: config.device.serialNumber,
+    dumpRaw: config.device.dumpRaw,
   });
 
-  const gestureDetector = new GestureDetector({
-    longPressMs: config.gestures.longPressMs,
-    doublePressMs: config.gestures.doublePressMs,
-  });
+  const gestureDetector = new GestureDetector(config.gestures);
+  if (options.inputPaused) gestureDetector.pause();
 
   const notificationServer = new NotificationServer(config);
 
   const statusListeners: Array<(status: BridgeStatus) => void> = [];
   let connected = false;
   let portPath: string | null = null;
+  let firmwareVersion: string | null = null;
   let pingInterval: ReturnType<typeof setInterval> | null = null;
+  let currentConfig = config; // Follows hot reloads
   let handedness = config.handedness;
+  let ignoreButtons = config.device.ignoreButtons ?? [];
 
   const LOG_MAX = 500;
   const logBuffer: LogEntry[] = [];
@@ -99,6 +117,8 @@ export async function startBridge(configPath: string): Promise<BridgeHandle> {
       connected,
       portPath,
       pendingCount: notificationServer.hasPending() ? 1 : 0,
+      inputPaused: gestureDetector.isPaused(),
+      firmwareVersion,
     };
     for (const cb of statusListeners) cb(status);
   }
@@ -107,33 +127,56 @@ export async function startBridge(configPath: string): Promise<BridgeHandle> {
   serialDevice.on('button', ({ buttonId, pressed }) => {
     pushLog('in', 'button', `${buttonId} ${pressed ? 'pressed' : 'released'}`);
     const num = parseInt(buttonId.replace('key', ''));
-    const remapped = `key${remapButtonIndex(num, handedness)}`;
-    gestureDetector.handleButton(remapped, pressed);
+    const logical = remapButtonIndex(num, handedness);
+    if (ignoreButtons.includes(logical)) return;
+    gestureDetector.handleButton(`key${logical}`, pressed);
   });
 
   serialDevice.on('connected', () => {
     connected = true;
-    portPath = config.device.port ?? null;
+    portPath = serialDevice.getInfo()?.path ?? null;
     pushLog('sys', 'connected', `Connected${portPath ? ` — ${portPath}` : ''}`);
     emitStatus();
-    serialDevice.sendStatus('Connected');
+    serialDevice.sendStatus(gestureDetector.isPaused() ? 'Input paused' : 'Connected');
+    serialDevice.requestVersion();
 
     // Send button labels from config
-    const labels = extractLabelsForDisplay(config, handedness);
+    const labels = extractLabelsForDisplay(currentConfig, handedness);
     pushLog('out', 'labels', labels.join(' | '));
     serialDevice.sendLabels(labels);
 
+    // Restore a notification that arrived or was showing while disconnected
+    const current = notificationServer.currentNotification();
+    if (current) {
+      pushLog('out', 'display', current.text.length > 60 ? current.text.slice(0, 60) + '…' : current.text);
+      serialDevice.sendText(current.text);
+    }
+
     pingInterval = setInterval(() => serialDevice.sendPing(), 5000);
   });
 
+  serialDevice.on('version', (version: string) => {
+    firmwareVersion = version;
+    pushLog('in', 'version', `Firmware ${version}`);
+    console.log(`Firmware version: ${version}`);
+    emitStatus();
+  });
+
   serialDevice.on('disconnected', () => {
     connected = false;
     portPath = null;
+    firmwareVersion = null;
     pushLog('sys', 'disconnected', 'Disconnected');
     if (pingInterval) { clearInterval(pingInterval); pingInterval = null; }
+    // Releases in flight are lost with the port; don't carry half-finished gestures over
+    gestureDetector.reset();
     emitStatus();
   });
 
+  serialDevice.on('reconnecting', ({ attempt, delayMs }) => {
+    pushLog('sys', 'reconnecting', `Retrying in ${delayMs / 1000}s (attempt ${attempt})`);
+  });
+
   // Gesture events → Notification server
   gestureDetector.on('gesture', ({ buttonId, gesture }) => {
     pushLog('in', 'gesture', `${buttonId} ${gesture}`);
@@ -163,11 +206,11 @@ export async function startBridge(configPath: string): Promise<BridgeHandle> {
   configWatcher.on('reload', (newConfig) => {
     pushLog('sys', 'config', 'Configuration reloaded');
     console.log('Applying new configuration...');
+    currentConfig = newConfig;
     handedness = newConfig.handedness;
-    gestureDetector.updateConfig({
-      longPressMs: newConfig.gestures.longPressMs,
-      doublePressMs: newConfig.gestures.doublePressMs,
-    });
+    ignoreButtons = newConfig.device.ignoreButtons ?? [];
+    serialDevice.setDumpRaw(!!newConfig.device.dumpRaw);
+    gestureDetector.updateConfig(newConfig.gestures);
     notificationServer.updateConfig(newConfig);
 
     // Update button labels if connected
@@ -188,11 +231,23 @@ export async function startBridge(configPath: string): Promise<BridgeHandle> {
       if (pingInterval) { clearInterval(pingInterval); pingInterval = null; }
       configWatcher.stop();
       notificationServer.stop();
+      // Leave the screen blank rather than showing a stale prompt. Writes are
+      // synchronous, so both frames are handed to the port before it closes.
+      if (connected) {
+        serialDevice.clearDisplay();
+        serialDevice.sendStatus('Disconnected');
+      }
       serialDevice.disconnect();
       gestureDetector.reset();
     },
     getStatus(): BridgeStatus {
-      return { connected, portPath, pendingCount: notificationServer.hasPending() ? 1 : 0 };
+      return {
+        connected,
+        portPath,
+        pendingCount: notificationServer.hasPending() ? 1 : 0,
+        inputPaused: gestureDetector.isPaused(),
+        firmwareVersion,
+      };
     },
     onStatusChange(cb: (status: BridgeStatus) => void) {
       statusListeners.push(cb);
@@ -224,5 +279,16 @@ export async function startBridge(configPath: string): Promise<BridgeHandle> {
       pushLog('out', 'labels', labels.join(' | '));
       return serialDevice.sendLabels(labels);
     },
+    setInputPaused(paused: boolean) {
+      if (paused === gestureDetector.isPaused()) return;
+      if (paused) {
+        gestureDetector.pause();
+      } else {
+        gestureDetector.resume();
+      }
+      pushLog('sys', 'input', paused ? 'Input paused' : 'Input resumed');
+      if (connected) serialDevice.sendStatus(paused ? 'Input paused' : 'Connected');
+      emitStatus();
+    },
   };
 }
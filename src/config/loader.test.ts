@@ -0,0 +1,172 @@
+import { describe, test, expect, afterEach } from 'bun:test';
+import { mkdtempSync, writeFileSync, rmSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { loadConfig, validateConfig, withDefaults, CONFIG_VERSION } from './loader.js';
+
+const dirs: string[] = [];
+
+function writeConfig(yaml: string): string {
+  const dir = mkdtempSync(join(tmpdir(), 'camel-pad-'));
+  dirs.push(dir);
+  const path = join(dir, 'config.yaml');
+  writeFileSync(path, yaml, 'utf8');
+  return path;
+}
+
+afterEach(() => {
+  for (const dir of dirs.splice(0)) rmSync(dir, { recursive: true, force: true });
+});
+
+describe('migration', () => {
+  test('a v1 file loads as the current version with defaults filled in', () => {
+    const path = writeConfig([
+      'device: { vendorId: 0x303A, productId: 0x1001 }',
+      'gestures: { longPressMs: 800 }',
+      'keys:',
+      '  key0:',
+      '    press: { action: approve, label: "Yes" }',
+      '    double_press: { action: approve-all, label: "Always" }',
+      '    long_press: { action: deny, label: "No" }',
+    ].join('\n'));
+
+    const config = loadConfig(path);
+    expect(config.version).toBe(CONFIG_VERSION);
+    expect(config.keys.key0).toEqual({
+      press: { action: 'approve', label: 'Yes' },
+      doublePress: { action: 'approve-all', label: 'Always' },
+      longPress: { action: 'deny', label: 'No' },
+    });
+    expect(config.gestures).toEqual({
+      longPressMs: 800,
+      doublePressMs: 300,
+      stuckResetMs: 30000,
+      longPress: true,
+      doublePress: true,
+    });
+    expect(config.defaults.timeoutMs).toBe(30000);
+    expect(validateConfig(config)).toEqual([]);
+  });
+
+  test('a file newer than this build is left alone and rejected', () => {
+    const path = writeConfig(`version: ${CONFIG_VERSION + 1}\ndevice: { port: /dev/cu.usbmodem1 }`);
+    const config = loadConfig(path);
+    expect(config.version).toBe(CONFIG_VERSION + 1);
+    expect(validateConfig(config)).toEqual([
+      `config version ${CONFIG_VERSION + 1} is newer than this camel-pad supports (${CONFIG_VERSION})`,
+    ]);
+  });
+
+  test('clamps a timeout saved by the old settings UI', () => {
+    const path = writeConfig([
+      'version: 2',
+      'device: { vendorId: 0x303A, productId: 0x1001 }',
+      'defaults: { timeoutMs: 180000 }',
+    ].join('\n'));
+
+    const config = loadConfig(path);
+    expect(config.version).toBe(CONFIG_VERSION);
+    expect(config.defaults.timeoutMs).toBe(120000);
+    expect(validateConfig(config)).toEqual([]);
+  });
+
+  test('rejects an out-of-range timeout in a current-version file', () => {
+    const path = writeConfig([
+      `version: ${CONFIG_VERSION}`,
+      'device: { vendorId: 0x303A, productId: 0x1001 }',
+      'defaults: { timeoutMs: 180000 }',
+    ].join('\n'));
+
+    expect(validateConfig(loadConfig(path))).toContain('defaults.timeoutMs must be between 1000 and 120000');
+  });
+});
+
+describe('validateConfig', () => {
+  test('accepts the shipped example config', () => {
+    const config = loadConfig(join(import.meta.dir, '../../config.example.yaml'));
+    expect(config.version).toBe(CONFIG_VERSION);
+    expect(validateConfig(config)).toEqual([]);
+  });
+
+  test('rejects gesture flags that YAML read as strings', () => {
+    const path = writeConfig([
+      'device: { port: /dev/cu.usbmodem1 }',
+      'gestures: { doublePress: no, longPress: off }',
+    ].join('\n'));
+
+    expect(validateConfig(loadConfig(path))).toEqual([
+      'gestures.longPress must be true or false',
+      'gestures.doublePress must be true or false',
+    ]);
+  });
+
+  test('rejects a mapping enabled flag that YAML read as a string', () => {
+    const path = writeConfig([
+      'device: { port: /dev/cu.usbmodem1 }',
+      'keys:',
+      '  key0:',
+      '    press: { action: approve, label: "Yes", enabled: no }',
+    ].join('\n'));
+
+    expect(validateConfig(loadConfig(path))).toEqual(['keys.key0.press.enabled must be true or false']);
+  });
+
+  test('rejects out-of-range and non-numeric gesture timings', () => {
+    const base = { device: { port: '/dev/cu.usbmodem1' } };
+    const gestures = withDefaults({}).gestures;
+    const check = (overrides: Record<string, unknown>) =>
+      validateConfig(withDefaults({ ...base, gestures: { ...gestures, ...overrides } as typeof gestures }));
+
+    expect(check({ longPressMs: -5 })).toEqual(['gestures.longPressMs must be between 1 and 5000']);
+    expect(check({ doublePressMs: 999999 })).toEqual(['gestures.doublePressMs must be between 1 and 2000']);
+
+    const stuck = ['gestures.stuckResetMs must be greater than gestures.longPressMs and at most 600000'];
+    expect(check({ stuckResetMs: gestures.longPressMs })).toEqual(stuck);
+    expect(check({ stuckResetMs: 100 })).toEqual(stuck);
+    expect(check({ stuckResetMs: 600001 })).toEqual(stuck);
+    expect(check({ stuckResetMs: NaN })).toEqual(stuck);
+    expect(check({ stuckResetMs: 600000 })).toEqual([]);
+  });
+
+  test('rejects a stuckResetMs that YAML read as a string', () => {
+    const path = writeConfig([
+      'device: { port: /dev/cu.usbmodem1 }',
+      'gestures: { stuckResetMs: 30s }',
+    ].join('\n'));
+
+    expect(validateConfig(loadConfig(path))).toEqual([
+      'gestures.stuckResetMs must be greater than gestures.longPressMs and at most 600000',
+    ]);
+  });
+
+  test('rejects an unquoted numeric serial number', () => {
+    const path = writeConfig('device: { vendorId: 0x303A, productId: 0x1001, serialNumber: 0001 }');
+    expect(validateConfig(loadConfig(path))).toEqual([
+      'device.serialNumber must be a string; quote it, e.g. serialNumber: "1"',
+    ]);
+
+    const quoted = writeConfig('device: { vendorId: 0x303A, productId: 0x1001, serialNumber: "0001" }');
+    expect(loadConfig(quoted).device.serialNumber).toBe('0001');
+    expect(validateConfig(loadConfig(quoted))).toEqual([]);
+  });
+
+  test('accepts only key indices 0-3 in ignoreButtons', () => {
+    const check = (ignoreButtons: unknown) =>
+      validateConfig(withDefaults({ device: { port: '/dev/cu.usbmodem1', ignoreButtons: ignoreButtons as number[] } }));
+    const error = ['device.ignoreButtons must be a list of key indices from 0 to 3'];
+
+    expect(check([0, 3])).toEqual([]);
+    expect(check([])).toEqual([]);
+    expect(check([4])).toEqual(error);
+    expect(check([-1])).toEqual(error);
+    expect(check([1.5])).toEqual(error);
+    expect(check(['3'])).toEqual(error);
+    expect(check(3)).toEqual(error);
+  });
+
+  test('checks a partial settings form once defaults are filled in', () => {
+    const form = { device: { port: '/dev/cu.usbmodem1' }, defaults: { timeoutMs: 300000 } };
+    expect(validateConfig(withDefaults(form))).toEqual(['defaults.timeoutMs must be between 1000 and 120000']);
+    expect(validateConfig(withDefaults({ device: { port: '/dev/cu.usbmodem1' } }))).toEqual([]);
+  });
+});
@@ -2,7 +2,20 @@ import { readFileSync } from 'fs';
 import { parse } from 'yaml';
 import type { Config } from '../types.js';
 
+/**
+ * Current config file format.
+ *   1: unversioned; key mappings may use snake_case gesture names
+ *   2: adds `version`; gesture names are camelCase (doublePress, longPress)
+ *   3: defaults.timeoutMs is limited to 1000-120000
+ */
+export const CONFIG_VERSION = 3;
+
+// The plugin hook works in whole seconds and is killed after 120s
+const TIMEOUT_MIN_MS = 1000;
+const TIMEOUT_MAX_MS = 120000;
+
 const DEFAULT_CONFIG: Config = {
+  version: CONFIG_VERSION,
   device: {},
   server: {
     port: 52914,
@@ -11,6 +24,9 @@ const DEFAULT_CONFIG: Config = {
   gestures: {
     longPressMs: 500,
     doublePressMs: 300,
+    stuckResetMs: 30000,
+    longPress: true,
+    doublePress: true,
   },
   keys: {},
   defaults: {
@@ -19,10 +35,20 @@ const DEFAULT_CONFIG: Config = {
   handedness: 'right',
 };
 
+// Migration notes already printed, so that the tray start, the watcher and
+// every settings popover don't repeat them for the same unchanged file
+const reportedMigrations = new Set<string>();
+
 export function loadConfig(path: string): Config {
   try {
     const content = readFileSync(path, 'utf8');
-    const parsed = parse(content) as Partial<Config>;
+    const notes: string[] = [];
+    const parsed = migrateConfig(parse(content) ?? {}, notes) as Partial<Config>;
+    const report = `${path}\n${notes.join('\n')}`;
+    if (notes.length > 0 && !reportedMigrations.has(report)) {
+      reportedMigrations.add(report);
+      for (const note of notes) console.log(`${path}: ${note}`);
+    }
     return mergeConfig(DEFAULT_CONFIG, parsed);
   } catch (err) {
     if ((err as NodeJS.ErrnoException).code === 'ENOENT') {
@@ -33,8 +59,60 @@ export function loadConfig(path: string): Config {
   }
 }
 
+/**
+ * Upgrade a parsed config file from an older format to CONFIG_VERSION.
+ * Files newer than this build understands are returned untouched so that
+ * validateConfig can report them. What changed is appended to `notes`.
+ */
+function migrateConfig(raw: any, notes: string[]): any {
+  const version = raw.version ?? 1;
+  if (version >= CONFIG_VERSION) return raw;
+
+  const migrated = { ...raw };
+
+  if (version < 2 && migrated.keys) {
+    const renames: Record<string, string> = {
+      double_press: 'doublePress',
+      long_press: 'longPress',
+    };
+    migrated.keys = Object.fromEntries(
+      Object.entries(migrated.keys as Record<string, any>).map(([keyId, mapping]) => {
+        const upgraded = { ...mapping };
+        for (const [from, to] of Object.entries(renames)) {
+          if (from in upgraded) {
+            upgraded[to] ??= upgraded[from];
+            delete upgraded[from];
+          }
+        }
+        return [keyId, upgraded];
+      }),
+    );
+  }
+
+  // The settings UI used to allow timeouts up to 300s; bring saved values
+  // into range rather than refusing to start
+  const timeoutMs = migrated.defaults?.timeoutMs;
+  if (version < 3 && typeof timeoutMs === 'number') {
+    const clamped = Math.min(Math.max(timeoutMs, TIMEOUT_MIN_MS), TIMEOUT_MAX_MS);
+    if (clamped !== timeoutMs) {
+      notes.push(`defaults.timeoutMs ${timeoutMs} is out of range, using ${clamped}`);
+      migrated.defaults = { ...migrated.defaults, timeoutMs: clamped };
+    }
+  }
+
+  notes.push(`migrated config from version ${version} to ${CONFIG_VERSION}`);
+  migrated.version = CONFIG_VERSION;
+  return migrated;
+}
+
+/** Fill in defaults for everything a partial config (e.g. a settings form) leaves out. */
+export function withDefaults(overrides: Partial<Config>): Config {
+  return mergeConfig(DEFAULT_CONFIG, overrides);
+}
+
 function mergeConfig(defaults: Config, overrides: Partial<Config>): Config {
   return {
+    version: overrides.version ?? defaults.version,
     device: {
       ...defaults.device,
       ...overrides.device,
@@ -59,20 +137,60 @@ function mergeConfig(defaults: Config, overrides: Partial<Config>): Config {
 export function validateConfig(config: Config): string[] {
   const errors: string[] = [];
 
+  if (config.version > CONFIG_VERSION) {
+    errors.push(`config version ${config.version} is newer than this camel-pad supports (${CONFIG_VERSION})`);
+  }
+
   const hasPort = !!config.device.port;
   const hasIds = config.device.vendorId && config.device.vendorId > 0 &&
                  config.device.productId && config.device.productId > 0;
   if (!hasPort && !hasIds) {
     errors.push('device.port or both device.vendorId and device.productId must be set');
   }
+  // YAML reads an unquoted serial like 0001 as the number 1, which never matches
+  const serial = config.device.serialNumber;
+  if (serial !== undefined && typeof serial !== 'string') {
+    errors.push(`device.serialNumber must be a string; quote it, e.g. serialNumber: "${serial}"`);
+  }
+  const ignored = config.device.ignoreButtons;
+  if (ignored !== undefined &&
+      (!Array.isArray(ignored) || ignored.some(i => !Number.isInteger(i) || i < 0 || i > 3))) {
+    errors.push('device.ignoreButtons must be a list of key indices from 0 to 3');
+  }
   if (!config.server.port || config.server.port <= 0 || config.server.port > 65535) {
     errors.push('server.port must be between 1 and 65535');
   }
-  if (config.gestures.longPressMs <= 0) {
-    errors.push('gestures.longPressMs must be positive');
+  // Written as !(in range) so that NaN and strings like "30s" fail too
+  const { longPressMs, doublePressMs, stuckResetMs } = config.gestures;
+  if (!(typeof longPressMs === 'number' && longPressMs > 0 && longPressMs <= 5000)) {
+    errors.push('gestures.longPressMs must be between 1 and 5000');
+  }
+  if (!(typeof doublePressMs === 'number' && doublePressMs > 0 && doublePressMs <= 2000)) {
+    errors.push('gestures.doublePressMs must be between 1 and 2000');
   }
-  if (config.gestures.doublePressMs <= 0) {
-    errors.push('gestures.doublePressMs must be positive');
+  if (!(typeof stuckResetMs === 'number' && stuckResetMs > longPressMs && stuckResetMs <= 600000)) {
+    errors.push('gestures.stuckResetMs must be greater than gestures.longPressMs and at most 600000');
+  }
+  // YAML 1.2 reads `no`/`off` as strings, which would leave the gesture on
+  for (const flag of ['longPress', 'doublePress'] as const) {
+    if (typeof config.gestures[flag] !== 'boolean') {
+      errors.push(`gestures.${flag} must be true or false`);
+    }
+  }
+  if (!(config.defaults.timeoutMs >= TIMEOUT_MIN_MS && config.defaults.timeoutMs <= TIMEOUT_MAX_MS)) {
+    errors.push(`defaults.timeoutMs must be between ${TIMEOUT_MIN_MS} and ${TIMEOUT_MAX_MS}`);
+  }
+
+  for (const [keyId, keyMapping] of Object.entries(config.keys)) {
+    for (const [gesture, mapping] of Object.entries(keyMapping ?? {})) {
+      if (mapping?.cooldownMs !== undefined &&
+          (typeof mapping.cooldownMs !== 'number' || mapping.cooldownMs < 0)) {
+        errors.push(`keys.${keyId}.${gesture}.cooldownMs must be a non-negative number`);
+      }
+      if (mapping?.enabled !== undefined && typeof mapping.enabled !== 'boolean') {
+        errors.push(`keys.${keyId}.${gesture}.enabled must be true or false`);
+      }
+    }
   }
 
   return errors;
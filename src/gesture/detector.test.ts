@@ -0,0 +1,123 @@
+import { describe, test, expect, beforeEach, afterEach } from 'bun:test';
+import { GestureDetector } from './detector.js';
+import { installFakeClock, type FakeClock } from '../testing/fake-clock.js';
+import type { GestureConfig, GestureType } from './types.js';
+
+const CONFIG: GestureConfig = {
+  longPressMs: 500,
+  doublePressMs: 300,
+  stuckResetMs: 30000,
+  longPress: true,
+  doublePress: true,
+};
+
+let clock: FakeClock;
+let detector: GestureDetector;
+let gestures: string[];
+
+function setup(overrides: Partial<GestureConfig> = {}) {
+  detector = new GestureDetector({ ...CONFIG, ...overrides });
+  gestures = [];
+  detector.on('gesture', ({ buttonId, gesture }: { buttonId: string; gesture: GestureType }) => {
+    gestures.push(`${buttonId} ${gesture}`);
+  });
+}
+
+function tap(buttonId = 'key0') {
+  detector.handleButton(buttonId, true);
+  detector.handleButton(buttonId, false);
+}
+
+beforeEach(() => {
+  clock = installFakeClock();
+  setup();
+});
+
+afterEach(() => {
+  detector.reset();
+  clock.restore();
+});
+
+describe('stuck reset', () => {
+  test('a second press whose release never arrives is abandoned', () => {
+    tap();
+    detector.handleButton('key0', true); // doublePressed, release lost
+
+    clock.advance(CONFIG.stuckResetMs);
+    expect(gestures).toEqual([]);
+
+    // Detection starts over; without the reset this release would complete the double press
+    tap();
+    clock.advance(CONFIG.doublePressMs);
+    expect(gestures).toEqual(['key0 press']);
+  });
+
+  test('a held press without long-press is abandoned', () => {
+    setup({ longPress: false });
+    detector.handleButton('key0', true);
+
+    clock.advance(CONFIG.stuckResetMs);
+    detector.handleButton('key0', false); // late release is ignored
+    clock.advance(CONFIG.doublePressMs);
+    expect(gestures).toEqual([]);
+  });
+
+  test('a release before the timeout cancels it', () => {
+    detector.handleButton('key0', true);
+    clock.advance(100);
+    detector.handleButton('key0', false);
+    clock.advance(CONFIG.stuckResetMs);
+    expect(gestures).toEqual(['key0 press']);
+  });
+});
+
+describe('gesture type flags', () => {
+  test('with doublePress off a quick press fires on release', () => {
+    setup({ doublePress: false });
+    tap();
+    expect(gestures).toEqual(['key0 press']);
+
+    // A second tap is another press, not a double press
+    tap();
+    expect(gestures).toEqual(['key0 press', 'key0 press']);
+  });
+
+  test('with longPress off a held button never emits longPress', () => {
+    setup({ longPress: false });
+    detector.handleButton('key0', true);
+    clock.advance(CONFIG.longPressMs * 4);
+    detector.handleButton('key0', false);
+    clock.advance(CONFIG.doublePressMs);
+    expect(gestures).toEqual(['key0 press']);
+  });
+});
+
+describe('pause', () => {
+  test('events while paused produce no gestures', () => {
+    detector.pause();
+    tap();
+    detector.handleButton('key1', true);
+    clock.advance(CONFIG.stuckResetMs);
+    expect(gestures).toEqual([]);
+  });
+
+  test('pausing abandons a gesture in progress', () => {
+    tap();
+    detector.pause();
+    clock.advance(CONFIG.doublePressMs);
+    expect(gestures).toEqual([]);
+  });
+
+  test('resume restores detection', () => {
+    detector.pause();
+    detector.handleButton('key0', true); // dropped; its release must not count either
+    detector.resume();
+    detector.handleButton('key0', false);
+    clock.advance(CONFIG.doublePressMs);
+    expect(gestures).toEqual([]);
+
+    tap();
+    clock.advance(CONFIG.doublePressMs);
+    expect(gestures).toEqual(['key0 press']);
+  });
+});
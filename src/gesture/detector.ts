@@ -6,11 +6,13 @@ interface ButtonContext {
   pressTime: number;
   longPressTimer: ReturnType<typeof setTimeout> | null;
   doublePressTimer: ReturnType<typeof setTimeout> | null;
+  stuckTimer: ReturnType<typeof setTimeout> | null;
 }
 
 export class GestureDetector extends EventEmitter {
   private config: GestureConfig;
   private buttons: Map<string, ButtonContext> = new Map();
+  private paused = false;
 
   constructor(config: GestureConfig) {
     super();
@@ -22,6 +24,8 @@ export class GestureDetector extends EventEmitter {
   }
 
   handleButton(buttonId: string, pressed: boolean): void {
+    if (this.paused) return;
+
     let ctx = this.buttons.get(buttonId);
     if (!ctx) {
       ctx = {
@@ -29,6 +33,7 @@ export class GestureDetector extends EventEmitter {
         pressTime: 0,
         longPressTimer: null,
         doublePressTimer: null,
+        stuckTimer: null,
       };
       this.buttons.set(buttonId, ctx);
     }
@@ -46,6 +51,8 @@ export class GestureDetector extends EventEmitter {
     switch (ctx.state) {
       case 'idle':
         ctx.state = 'pressed';
+        this.startStuckTimer(buttonId, ctx);
+        if (!this.config.longPress) break;
         // Start long press timer
         ctx.longPressTimer = setTimeout(() => {
           if (ctx.state === 'pressed') {
@@ -60,6 +67,7 @@ export class GestureDetector extends EventEmitter {
         // Second press within double-press window
         this.clearTimers(ctx);
         ctx.state = 'doublePressed';
+        this.startStuckTimer(buttonId, ctx);
         break;
 
       default:
@@ -73,6 +81,12 @@ export class GestureDetector extends EventEmitter {
       case 'pressed':
         // Released before long press threshold
         this.clearTimers(ctx);
+        if (!this.config.doublePress) {
+          // Nothing to wait for, so don't add the double-press latency
+          ctx.state = 'idle';
+          this.emitGesture(buttonId, 'press');
+          break;
+        }
         ctx.state = 'waitDouble';
         // Start double press timer
         ctx.doublePressTimer = setTimeout(() => {
@@ -96,6 +110,19 @@ export class GestureDetector extends EventEmitter {
     }
   }
 
+  private startStuckTimer(buttonId: string, ctx: ButtonContext): void {
+    // A release lost to a USB glitch would otherwise leave the button
+    // stuck in a pressed state, ignoring every later press
+    ctx.stuckTimer = setTimeout(() => {
+      ctx.stuckTimer = null;
+      if (ctx.state === 'pressed' || ctx.state === 'doublePressed') {
+        console.warn(`No release for ${buttonId} after ${this.config.stuckResetMs}ms, resetting`);
+        this.clearTimers(ctx);
+        ctx.state = 'idle';
+      }
+    }, this.config.stuckResetMs);
+  }
+
   private clearTimers(ctx: ButtonContext): void {
     if (ctx.longPressTimer) {
       clearTimeout(ctx.longPressTimer);
@@ -105,6 +132,10 @@ export class GestureDetector extends EventEmitter {
       clearTimeout(ctx.doublePressTimer);
       ctx.doublePressTimer = null;
     }
+    if (ctx.stuckTimer) {
+      clearTimeout(ctx.stuckTimer);
+      ctx.stuckTimer = null;
+    }
   }
 
   private emitGesture(buttonId: string, gesture: GestureType): void {
@@ -112,6 +143,23 @@ export class GestureDetector extends EventEmitter {
     this.emit('gesture', event);
   }
 
+  /**
+   * Drop all button events until resume(). Gestures in progress are
+   * abandoned so a stale timer can't fire after resuming.
+   */
+  pause(): void {
+    this.paused = true;
+    this.reset();
+  }
+
+  resume(): void {
+    this.paused = false;
+  }
+
+  isPaused(): boolean {
+    return this.paused;
+  }
+
   reset(): void {
     for (const ctx of this.buttons.values()) {
       this.clearTimers(ctx);
@@ -8,6 +8,9 @@ export interface GestureEvent {
 export interface GestureConfig {
   longPressMs: number;
   doublePressMs: number;
+  stuckResetMs: number; // Give up on a press whose release never arrives
+  longPress: boolean;   // false: never emit longPress
+  doublePress: boolean; // false: emit press on release without waiting
 }
 
 export type ButtonState = 'idle' | 'pressed' | 'waitDouble' | 'doublePressed';
@@ -0,0 +1,97 @@
+import { describe, test, expect, mock, beforeEach, afterEach } from 'bun:test';
+import { mkdtempSync, rmSync, writeFileSync, unlinkSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { installFakeClock, type FakeClock } from '../testing/fake-clock.js';
+import type { PortInfo } from './discovery.js';
+import type { SerialDevice as SerialDeviceType } from './device.js';
+
+// A regular file opens like a port (stty complains and is ignored), so the
+// device can be made to appear and disappear by creating and deleting it.
+// Discovery picks from `attached` instead of scanning /dev; the mock outlives
+// this file, so it defers to the real lookup once a test is over.
+
+let attached: PortInfo[] | null = null;
+
+const realDiscovery = { ...(await import('./discovery.js')) };
+mock.module('./discovery.js', () => ({
+  ...realDiscovery,
+  findPort: async (vendorId: number, productId: number, serialNumber?: string) =>
+    attached
+      ? realDiscovery.selectPort(attached, vendorId, productId, serialNumber)
+      : realDiscovery.findPort(vendorId, productId, serialNumber),
+}));
+
+const { SerialDevice } = await import('./device.js');
+
+let dir: string;
+let clock: FakeClock;
+let device: SerialDeviceType | null;
+
+beforeEach(() => {
+  dir = mkdtempSync(join(tmpdir(), 'camel-pad-'));
+  clock = installFakeClock();
+  device = null;
+  attached = [];
+});
+
+afterEach(() => {
+  device?.disconnect();
+  clock.restore();
+  attached = null;
+  rmSync(dir, { recursive: true, force: true });
+});
+
+describe('getInfo', () => {
+  test('reports the discovered port while it is open', async () => {
+    const pads = ['F4:12:FA:00:00:01', 'F4:12:FA:00:00:02'].map((serialNumber, i) => {
+      const path = join(dir, `cu.usbmodem${i}`);
+      writeFileSync(path, '');
+      return { path, vendorId: '303a', productId: '1001', serialNumber };
+    });
+    attached = pads;
+
+    device = new SerialDevice({ vendorId: 0x303A, productId: 0x1001, serialNumber: 'F4:12:FA:00:00:02' });
+    expect(device.getInfo()).toBeNull();
+    expect(await device.connect()).toBe(true);
+    expect(device.getInfo()).toEqual(pads[1]);
+
+    device.disconnect();
+    expect(device.getInfo()).toBeNull();
+  });
+});
+
+describe('reconnect', () => {
+  test('backs off up to the cap, then starts over after connecting', async () => {
+    const path = join(dir, 'cu.usbmodemTEST');
+    device = new SerialDevice({ port: path });
+    const retries: Array<{ attempt: number; delayMs: number }> = [];
+    let connects = 0;
+    device.on('reconnecting', r => retries.push(r));
+    device.on('connected', () => connects++);
+
+    expect(await device.connect()).toBe(false);
+    for (const delayMs of [2000, 4000, 8000, 10000]) {
+      clock.advance(delayMs - 1);
+      expect(retries.length).toBe(retries.at(-1)!.attempt); // not retried early
+      clock.advance(1);
+    }
+    expect(retries).toEqual([
+      { attempt: 1, delayMs: 2000 },
+      { attempt: 2, delayMs: 4000 },
+      { attempt: 3, delayMs: 8000 },
+      { attempt: 4, delayMs: 10000 },
+      { attempt: 5, delayMs: 10000 },
+    ]);
+
+    writeFileSync(path, '');
+    clock.advance(10000);
+    expect(connects).toBe(1);
+    expect(device.isConnected()).toBe(true);
+
+    device.disconnect();
+    unlinkSync(path);
+    expect(await device.connect()).toBe(false);
+    expect(retries.at(-1)).toEqual({ attempt: 1, delayMs: 2000 });
+  });
+});
@@ -4,16 +4,20 @@ import { EventEmitter } from 'events';
 import {
   MSG_BUTTON, MSG_SET_LEDS,
   MSG_DISPLAY_TEXT, MSG_STATUS, MSG_CLEAR, MSG_SET_LABELS, MSG_HEARTBEAT, MSG_PING,
+  MSG_GET_VERSION, MSG_VERSION,
   SERIAL_BAUD,
 } from '../types.js';
-import { buildFrame, FrameParser } from './protocol.js';
+import { buildFrame, encodeText, formatHex, parseButtonPayload, FrameParser } from './protocol.js';
 import type { ParsedFrame } from './protocol.js';
 import { findPort } from './discovery.js';
+import type { PortInfo } from './discovery.js';
 
 export interface SerialDeviceConfig {
   port?: string;
   vendorId?: number;
   productId?: number;
+  serialNumber?: string;
+  dumpRaw?: boolean;
 }
 
 export interface ButtonEvent {
@@ -24,11 +28,13 @@ export interface ButtonEvent {
 export class SerialDevice extends EventEmitter {
   private config: SerialDeviceConfig;
   private fd: number | null = null;      // Non-blocking, for both reads and writes
-  private portPath: string | null = null;
+  private info: PortInfo | null = null;   // Port that was actually opened
   private parser = new FrameParser();
   private reconnectTimer: ReturnType<typeof setTimeout> | null = null;
+  private reconnectAttempts = 0;
   private pollTimer: ReturnType<typeof setInterval> | null = null;
-  private readonly RECONNECT_INTERVAL = 2000;
+  private readonly RECONNECT_BASE_MS = 2000;
+  private readonly RECONNECT_MAX_MS = 10000;
   private readonly POLL_INTERVAL = 10; // 100Hz polling
   private readonly WRITE_RETRY_MAX = 50;
   private readonly WRITE_RETRY_DELAY_MS = 5;
@@ -40,36 +46,40 @@ export class SerialDevice extends EventEmitter {
   }
 
   async connect(): Promise<boolean> {
-    let portPath = this.config.port;
+    let port: PortInfo | undefined = this.config.port ? { path: this.config.port } : undefined;
 
-    if (!portPath && this.config.vendorId && this.config.productId) {
-      portPath = await findPort(this.config.vendorId, this.config.productId);
-      if (!portPath) {
+    if (!port && this.config.vendorId && this.config.productId) {
+      port = await findPort(this.config.vendorId, this.config.productId, this.config.serialNumber);
+      if (!port) {
+        const serial = this.config.serialNumber ? ` serial=${this.config.serialNumber}` : '';
         console.error(
-          `Device not found: vendor=0x${this.config.vendorId.toString(16)} product=0x${this.config.productId.toString(16)}`
+          `Device not found: vendor=0x${this.config.vendorId.toString(16)} product=0x${this.config.productId.toString(16)}${serial}`
         );
         this.scheduleReconnect();
         return false;
       }
     }
 
-    if (!portPath) {
+    if (!port) {
       console.error('No serial port configured and no vendor/product IDs for auto-discovery');
       return false;
     }
 
+    const portPath = port.path;
     try {
       // Configure serial port with stty
       this.configurePort(portPath);
 
       // Open non-blocking fd for both reads and writes
       this.fd = openSync(portPath, constants.O_RDWR | constants.O_NOCTTY | constants.O_NONBLOCK);
-      this.portPath = portPath;
+      this.info = port;
+      this.reconnectAttempts = 0;
 
       // Start polling for incoming data
       this.startPolling();
 
-      console.log(`Connected to serial port ${portPath}`);
+      const ids = port.vendorId ? ` [${port.vendorId}:${port.productId}]` : '';
+      console.log(`Connected to serial port ${portPath}${ids}`);
       this.emit('connected');
       return true;
     } catch (err: any) {
@@ -108,7 +118,12 @@ export class SerialDevice extends EventEmitter {
       try {
         const bytesRead = readSync(this.fd, buf, 0, buf.length, null);
         if (bytesRead > 0) {
-          this.parser.parse(buf.subarray(0, bytesRead));
+          const chunk = buf.subarray(0, bytesRead);
+          if (this.config.dumpRaw) {
+            // Logged before parsing so malformed frames are visible too
+            console.log(`RX ${bytesRead}B: ${formatHex(chunk)}`);
+          }
+          this.parser.parse(chunk);
         }
       } catch (err: any) {
         // EAGAIN/EWOULDBLOCK is normal for non-blocking reads with no data
@@ -129,11 +144,16 @@ export class SerialDevice extends EventEmitter {
   private scheduleReconnect(): void {
     if (this.reconnectTimer) return;
 
+    // Exponential backoff so a missing device isn't polled in a tight loop
+    const attempt = ++this.reconnectAttempts;
+    const delayMs = Math.min(this.RECONNECT_BASE_MS * 2 ** (attempt - 1), this.RECONNECT_MAX_MS);
+    this.emit('reconnecting', { attempt, delayMs });
+
     this.reconnectTimer = setTimeout(() => {
       this.reconnectTimer = null;
-      console.log('Attempting to reconnect...');
+      console.log(`Attempting to reconnect (attempt ${attempt})...`);
       this.connect();
-    }, this.RECONNECT_INTERVAL);
+    }, delayMs);
   }
 
   private sleepMs(ms: number): void {
@@ -145,17 +165,17 @@ export class SerialDevice extends EventEmitter {
 
   private handleFrame(frame: ParsedFrame): void {
     switch (frame.msgType) {
-      case MSG_BUTTON: {
-        if (frame.payload.length >= 2) {
-          const buttonId = `key${frame.payload[0]}`;
-          const pressed = frame.payload[1] === 1;
-          this.emit('button', { buttonId, pressed } as ButtonEvent);
+      case MSG_BUTTON:
+        for (const { index, pressed } of parseButtonPayload(frame.payload)) {
+          this.emit('button', { buttonId: `key${index}`, pressed } as ButtonEvent);
         }
         break;
-      }
       case MSG_HEARTBEAT:
         this.emit('heartbeat', frame.payload[0]);
         break;
+      case MSG_VERSION:
+        this.emit('version', frame.payload.toString('utf8'));
+        break;
     }
   }
 
@@ -166,11 +186,11 @@ export class SerialDevice extends EventEmitter {
   }
 
   sendText(text: string): boolean {
-    return this.sendMessage(MSG_DISPLAY_TEXT, Buffer.from(text, 'utf8'));
+    return this.sendMessage(MSG_DISPLAY_TEXT, encodeText(text));
   }
 
   sendStatus(text: string): boolean {
-    return this.sendMessage(MSG_STATUS, Buffer.from(text, 'utf8'));
+    return this.sendMessage(MSG_STATUS, encodeText(text));
   }
 
   sendLabels(labels: string[]): boolean {
@@ -203,6 +223,11 @@ export class SerialDevice extends EventEmitter {
     return this.sendMessage(MSG_PING);
   }
 
+  /** Ask the firmware for its version; the reply arrives as a 'version' event. */
+  requestVersion(): boolean {
+    return this.sendMessage(MSG_GET_VERSION);
+  }
+
   private sendMessage(msgType: number, payload?: Buffer): boolean {
     if (this.fd === null) {
       console.error('Failed to send: fd is null');
@@ -266,14 +291,24 @@ export class SerialDevice extends EventEmitter {
       try { closeSync(this.fd); } catch { /* ignore */ }
       this.fd = null;
     }
-    if (this.portPath !== null) {
-      this.portPath = null;
+    if (this.info !== null) {
+      this.info = null;
       this.parser.reset();
       this.emit('disconnected');
     }
   }
 
+  /** Turn raw RX logging on or off without reopening the port. */
+  setDumpRaw(enabled: boolean): void {
+    this.config.dumpRaw = enabled;
+  }
+
   isConnected(): boolean {
     return this.fd !== null;
   }
+
+  /** The port that is currently open, or null when disconnected. */
+  getInfo(): PortInfo | null {
+    return this.info ? { ...this.info } : null;
+  }
 }
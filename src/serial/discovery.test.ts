@@ -0,0 +1,118 @@
+import { describe, test, expect } from 'bun:test';
+import { parseIoregUsbDevices, selectPort } from './discovery.js';
+
+// Trimmed `ioreg -r -c IOUSBHostDevice -l` output: two identical pads, one of
+// them behind a hub (so it is listed inside the hub's tree and again on its own)
+const IOREG = `+-o USB2.0 Hub@01100000  <class IOUSBHostDevice, id 0x100000a10, registered, matched, active, busy 0 (20 ms), retain 25>
+  | {
+  |   "idProduct" = 2066
+  |   "USB Product Name" = "USB2.0 Hub"
+  |   "idVendor" = 1507
+  | }
+  |
+  +-o camel-pad@01110000  <class IOUSBHostDevice, id 0x100000a20, registered, matched, active, busy 0 (12 ms), retain 30>
+    | {
+    |   "idProduct" = 4097
+    |   "kUSBSerialNumberString" = "F4:12:FA:00:00:01"
+    |   "USB Product Name" = "camel-pad"
+    |   "idVendor" = 12346
+    |   "USB Serial Number" = "F4:12:FA:00:00:01"
+    | }
+    |
+    +-o IOUSBHostInterface@0  <class IOUSBHostInterface, id 0x100000a21, registered, matched, active, busy 0 (5 ms), retain 9>
+    | | {
+    | |   "idProduct" = 4097
+    | |   "bInterfaceClass" = 2
+    | |   "idVendor" = 12346
+    | | }
+    | |
+    | +-o AppleUSBACMControl  <class AppleUSBACMControl, id 0x100000a25, !registered, !matched, active, busy 0, retain 6>
+    |     {
+    |     }
+    |
+    +-o IOUSBHostInterface@1  <class IOUSBHostInterface, id 0x100000a22, registered, matched, active, busy 0 (5 ms), retain 9>
+      | {
+      |   "idProduct" = 4097
+      |   "bInterfaceClass" = 10
+      |   "idVendor" = 12346
+      | }
+      |
+      +-o AppleUSBACMData  <class AppleUSBACMData, id 0x100000a26, registered, matched, active, busy 0 (3 ms), retain 8>
+        | {
+        |   "CFBundleIdentifier" = "com.apple.driver.usb.cdc.acm"
+        | }
+        |
+        +-o IOSerialBSDClient  <class IOSerialBSDClient, id 0x100000a27, registered, matched, active, busy 0, retain 5>
+            {
+              "IOCalloutDevice" = "/dev/cu.usbmodem11101"
+              "IODialinDevice" = "/dev/tty.usbmodem11101"
+            }
+
++-o camel-pad@01110000  <class IOUSBHostDevice, id 0x100000a20, registered, matched, active, busy 0 (12 ms), retain 30>
+  | {
+  |   "idProduct" = 4097
+  |   "kUSBSerialNumberString" = "F4:12:FA:00:00:01"
+  |   "idVendor" = 12346
+  |   "USB Serial Number" = "F4:12:FA:00:00:01"
+  | }
+  |
+  +-o IOUSBHostInterface@1  <class IOUSBHostInterface, id 0x100000a22, registered, matched, active, busy 0 (5 ms), retain 9>
+    +-o AppleUSBACMData  <class AppleUSBACMData, id 0x100000a26, registered, matched, active, busy 0 (3 ms), retain 8>
+      +-o IOSerialBSDClient  <class IOSerialBSDClient, id 0x100000a27, registered, matched, active, busy 0, retain 5>
+          {
+            "IOCalloutDevice" = "/dev/cu.usbmodem11101"
+          }
+
++-o camel-pad@02100000  <class IOUSBHostDevice, id 0x100000b20, registered, matched, active, busy 0 (11 ms), retain 30>
+  | {
+  |   "idProduct" = 4097
+  |   "kUSBSerialNumberString" = "F4:12:FA:00:00:02"
+  |   "idVendor" = 12346
+  |   "USB Serial Number" = "F4:12:FA:00:00:02"
+  | }
+  |
+  +-o IOUSBHostInterface@1  <class IOUSBHostInterface, id 0x100000b22, registered, matched, active, busy 0 (5 ms), retain 9>
+    | {
+    |   "idProduct" = 4097
+    |   "idVendor" = 12346
+    | }
+    |
+    +-o AppleUSBACMData  <class AppleUSBACMData, id 0x100000b26, registered, matched, active, busy 0 (3 ms), retain 8>
+      +-o IOSerialBSDClient  <class IOSerialBSDClient, id 0x100000b27, registered, matched, active, busy 0, retain 5>
+          {
+            "IOCalloutDevice" = "/dev/cu.usbmodem2101"
+            "IODialinDevice" = "/dev/tty.usbmodem2101"
+          }
+
+`;
+
+describe('parseIoregUsbDevices', () => {
+  test('reads device-level serial numbers for each tty', () => {
+    expect(parseIoregUsbDevices(IOREG)).toEqual([
+      { path: '/dev/cu.usbmodem11101', vendorId: '303a', productId: '1001', serialNumber: 'F4:12:FA:00:00:01' },
+      { path: '/dev/cu.usbmodem2101', vendorId: '303a', productId: '1001', serialNumber: 'F4:12:FA:00:00:02' },
+    ]);
+  });
+
+  test('returns nothing without serial ports', () => {
+    expect(parseIoregUsbDevices('')).toEqual([]);
+  });
+});
+
+describe('selectPort', () => {
+  const ports = parseIoregUsbDevices(IOREG);
+
+  test('picks the pad with the configured serial number', () => {
+    expect(selectPort(ports, 0x303A, 0x1001, 'F4:12:FA:00:00:02')?.path).toBe('/dev/cu.usbmodem2101');
+    expect(selectPort(ports, 0x303A, 0x1001, 'F4:12:FA:00:00:01')?.path).toBe('/dev/cu.usbmodem11101');
+  });
+
+  test('finds nothing when the serial number matches no pad', () => {
+    expect(selectPort(ports, 0x303A, 0x1001, 'nope')).toBeUndefined();
+  });
+
+  test('falls back to the first match without a serial number', () => {
+    expect(selectPort(ports, 0x303A, 0x1001)?.path).toBe('/dev/cu.usbmodem11101');
+    expect(selectPort(ports, 0x303A, 0x1002)).toBeUndefined();
+  });
+});
@@ -5,6 +5,7 @@ export interface PortInfo {
   path: string;
   vendorId?: string;
   productId?: string;
+  serialNumber?: string;
 }
 
 /** List serial port device files that look like USB serial devices. */
@@ -22,64 +23,101 @@ export async function listPorts(): Promise<PortInfo[]> {
       path,
       vendorId: info?.vendorId,
       productId: info?.productId,
+      serialNumber: info?.serialNumber,
     };
   });
 }
 
 /**
- * Find a serial port by USB vendor and product ID.
- * Returns the device path, or undefined if not found.
+ * Find a serial port by USB vendor and product ID, optionally narrowed by
+ * USB serial number when several identical devices are attached.
+ * Returns the matching port, or undefined if not found.
  */
-export async function findPort(vendorId: number, productId: number): Promise<string | undefined> {
-  const ports = await listPorts();
+export async function findPort(
+  vendorId: number,
+  productId: number,
+  serialNumber?: string,
+): Promise<PortInfo | undefined> {
+  return selectPort(await listPorts(), vendorId, productId, serialNumber);
+}
+
+/** Pick the port findPort would open from an already-listed set. */
+export function selectPort(
+  ports: PortInfo[],
+  vendorId: number,
+  productId: number,
+  serialNumber?: string,
+): PortInfo | undefined {
   const vid = vendorId.toString(16).toLowerCase();
   const pid = productId.toString(16).toLowerCase();
 
-  const match = ports.find(p =>
+  const matches = ports.filter(p =>
     p.vendorId === vid && p.productId === pid
   );
 
-  return match?.path;
+  if (serialNumber) {
+    return matches.find(p => p.serialNumber === serialNumber);
+  }
+
+  if (matches.length > 1) {
+    console.warn(
+      `${matches.length} devices match vendor/product ID, using ${matches[0].path}; ` +
+      `set device.serialNumber to choose (${matches.map(p => p.serialNumber ?? '?').join(', ')})`
+    );
+  }
+  return matches[0];
 }
 
 interface UsbInfo {
   path: string;
   vendorId?: string;
   productId?: string;
+  serialNumber?: string;
 }
 
 /**
- * Parse macOS ioreg for AppleUSBACMData entries to get
- * vendor/product IDs alongside tty device paths.
+ * Run macOS ioreg over USB devices to get vendor/product IDs and serial
+ * numbers alongside tty device paths.
  */
 function getAcmDeviceInfo(): UsbInfo[] {
   try {
     const output = execSync(
-      'ioreg -r -c AppleUSBACMData -l 2>/dev/null',
+      'ioreg -r -c IOUSBHostDevice -l 2>/dev/null',
       { encoding: 'utf8', timeout: 5000 }
     );
+    return parseIoregUsbDevices(output);
+  } catch {
+    return [];
+  }
+}
 
-    const results: UsbInfo[] = [];
-    // Split into per-ACM-device blocks. Each top-level AppleUSBACMData entry
-    // contains idVendor/idProduct and a nested IOSerialBSDClient with the tty path.
-    const blocks = output.split(/(?=\+-o AppleUSBACMData\b)/);
+/**
+ * Parse `ioreg -r -c IOUSBHostDevice -l` output. The serial number only
+ * appears on the IOUSBHostDevice node, so each device's properties are read
+ * from its own block, which runs until the next device node and contains its
+ * interfaces, the AppleUSBACMData driver and the IOSerialBSDClient with the
+ * tty path. Devices behind a hub can be listed twice; the first copy wins.
+ */
+export function parseIoregUsbDevices(output: string): UsbInfo[] {
+  const results: UsbInfo[] = [];
+  const blocks = output.split(/(?=^[ |]*\+-o [^\n]*<class IOUSBHostDevice,)/m);
 
-    for (const block of blocks) {
-      const vidMatch = block.match(/"idVendor"\s*=\s*(\d+)/);
-      const pidMatch = block.match(/"idProduct"\s*=\s*(\d+)/);
-      const pathMatch = block.match(/"IOCalloutDevice"\s*=\s*"([^"]+)"/);
+  for (const block of blocks) {
+    const vidMatch = block.match(/"idVendor"\s*=\s*(\d+)/);
+    const pidMatch = block.match(/"idProduct"\s*=\s*(\d+)/);
+    const serialMatch = block.match(/"(?:USB Serial Number|kUSBSerialNumberString)"\s*=\s*"([^"]*)"/);
 
-      if (pathMatch) {
-        results.push({
-          path: pathMatch[1],
-          vendorId: vidMatch ? parseInt(vidMatch[1]).toString(16).toLowerCase() : undefined,
-          productId: pidMatch ? parseInt(pidMatch[1]).toString(16).toLowerCase() : undefined,
-        });
-      }
+    // A composite device may expose more than one CDC port
+    for (const pathMatch of block.matchAll(/"IOCalloutDevice"\s*=\s*"([^"]+)"/g)) {
+      if (results.some(r => r.path === pathMatch[1])) continue;
+      results.push({
+        path: pathMatch[1],
+        vendorId: vidMatch ? parseInt(vidMatch[1]).toString(16).toLowerCase() : undefined,
+        productId: pidMatch ? parseInt(pidMatch[1]).toString(16).toLowerCase() : undefined,
+        serialNumber: serialMatch?.[1] || undefined,
+      });
     }
-
-    return results;
-  } catch {
-    return [];
   }
+
+  return results;
 }
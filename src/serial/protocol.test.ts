@@ -0,0 +1,76 @@
+import { describe, test, expect } from 'bun:test';
+import { buildFrame, encodeText, formatHex, parseButtonPayload, FrameParser, type ParsedFrame } from './protocol.js';
+import { MAX_MSG_LEN, MSG_BUTTON, MSG_DISPLAY_TEXT } from '../types.js';
+
+function parseAll(data: Buffer): ParsedFrame[] {
+  const parser = new FrameParser();
+  const frames: ParsedFrame[] = [];
+  parser.on('frame', (frame: ParsedFrame) => frames.push(frame));
+  parser.parse(data);
+  return frames;
+}
+
+describe('buildFrame', () => {
+  test('accepts the largest body the firmware takes', () => {
+    const payload = Buffer.alloc(MAX_MSG_LEN - 1, 0x41);
+    const frame = buildFrame(MSG_DISPLAY_TEXT, payload);
+    expect(frame.length).toBe(MAX_MSG_LEN + 4);
+    expect(parseAll(frame)).toEqual([{ msgType: MSG_DISPLAY_TEXT, payload }]);
+  });
+
+  test('throws on an oversized body', () => {
+    expect(() => buildFrame(MSG_DISPLAY_TEXT, Buffer.alloc(MAX_MSG_LEN))).toThrow(/exceeds MAX_MSG_LEN/);
+  });
+});
+
+describe('encodeText', () => {
+  test('leaves text that fits alone', () => {
+    const text = 'a'.repeat(MAX_MSG_LEN - 1);
+    expect(encodeText(text).toString('utf8')).toBe(text);
+  });
+
+  test('drops a multibyte character straddling the limit whole', () => {
+    // '€' is 3 bytes and would occupy bytes 510-512
+    const text = 'a'.repeat(MAX_MSG_LEN - 2) + '€';
+    const encoded = encodeText(text);
+    expect(encoded.length).toBe(MAX_MSG_LEN - 2);
+    expect(encoded.toString('utf8')).toBe('a'.repeat(MAX_MSG_LEN - 2));
+    expect(() => buildFrame(MSG_DISPLAY_TEXT, encoded)).not.toThrow();
+  });
+
+  test('keeps a multibyte character that ends exactly at the limit', () => {
+    const text = 'a'.repeat(MAX_MSG_LEN - 4) + '€' + 'b';
+    expect(encodeText(text).toString('utf8')).toBe('a'.repeat(MAX_MSG_LEN - 4) + '€');
+  });
+});
+
+describe('parseButtonPayload', () => {
+  test('a single pair is one event', () => {
+    expect(parseButtonPayload(Buffer.from([2, 1]))).toEqual([{ index: 2, pressed: true }]);
+  });
+
+  test('coalesced pairs come out in order', () => {
+    const frame = buildFrame(MSG_BUTTON, Buffer.from([0, 1, 3, 1, 0, 0]));
+    const [{ payload }] = parseAll(frame);
+    expect(parseButtonPayload(payload)).toEqual([
+      { index: 0, pressed: true },
+      { index: 3, pressed: true },
+      { index: 0, pressed: false },
+    ]);
+  });
+
+  test('a trailing odd byte is ignored', () => {
+    expect(parseButtonPayload(Buffer.from([1, 0, 2]))).toEqual([{ index: 1, pressed: false }]);
+    expect(parseButtonPayload(Buffer.from([1]))).toEqual([]);
+  });
+});
+
+describe('formatHex', () => {
+  test('formats bytes as padded lowercase hex', () => {
+    expect(formatHex(Buffer.from([0xaa, 0x00, 0x03, 0x02, 0x01, 0x0f]))).toBe('aa 00 03 02 01 0f');
+  });
+
+  test('an empty buffer is an empty string', () => {
+    expect(formatHex(Buffer.alloc(0))).toBe('');
+  });
+});
@@ -6,6 +6,11 @@ export interface ParsedFrame {
   payload: Buffer;
 }
 
+/** Format bytes as space-separated hex, e.g. "aa 00 03 02 01 01". */
+export function formatHex(data: Buffer): string {
+  return Array.from(data, b => b.toString(16).padStart(2, '0')).join(' ');
+}
+
 function xorChecksum(data: Buffer, offset: number, length: number): number {
   let cs = 0;
   for (let i = offset; i < offset + length; i++) {
@@ -20,10 +25,16 @@ function xorChecksum(data: Buffer, offset: number, length: number): number {
  *
  * LEN = 1 (msgType) + payload.length
  * CHECKSUM = XOR of MSG_TYPE + PAYLOAD bytes
+ *
+ * Throws if LEN exceeds MAX_MSG_LEN — the firmware parser discards such
+ * frames, so sending one would silently do nothing.
  */
 export function buildFrame(msgType: number, payload?: Buffer): Buffer {
   const payloadLen = payload ? payload.length : 0;
   const bodyLen = 1 + payloadLen; // msgType + payload
+  if (bodyLen > MAX_MSG_LEN) {
+    throw new Error(`Frame body of ${bodyLen} bytes exceeds MAX_MSG_LEN (${MAX_MSG_LEN})`);
+  }
   const frame = Buffer.alloc(5 + payloadLen);
 
   frame[0] = FRAME_START_BYTE;
@@ -38,6 +49,30 @@ export function buildFrame(msgType: number, payload?: Buffer): Buffer {
   return frame;
 }
 
+/**
+ * Decode a MSG_BUTTON payload: one or more [button_id, pressed] pairs, in
+ * the order the firmware saw them. A trailing odd byte is ignored.
+ */
+export function parseButtonPayload(payload: Buffer): Array<{ index: number; pressed: boolean }> {
+  const events: Array<{ index: number; pressed: boolean }> = [];
+  for (let i = 0; i + 1 < payload.length; i += 2) {
+    events.push({ index: payload[i], pressed: payload[i + 1] === 1 });
+  }
+  return events;
+}
+
+/** UTF-8 encode text, truncated on a character boundary to fit in one frame. */
+export function encodeText(text: string): Buffer {
+  const maxLen = MAX_MSG_LEN - 1; // LEN includes the msgType byte
+  const encoded = Buffer.from(text, 'utf8');
+  if (encoded.length <= maxLen) return encoded;
+
+  // Back up over continuation bytes (10xxxxxx) so no character is split
+  let end = maxLen;
+  while (end > 0 && (encoded[end] & 0xC0) === 0x80) end--;
+  return encoded.subarray(0, end);
+}
+
 const enum ParserState {
   WAIT_START,
   READ_LEN_HI,
@@ -0,0 +1,79 @@
+/**
+ * Manual clock for tests: replaces the global timer functions and Date.now
+ * so timeouts only fire when the test advances time.
+ */
+export interface FakeClock {
+  now(): number;
+  /** Move time forward, firing every timer that comes due, in order. */
+  advance(ms: number): void;
+  /** Put the real timers and Date.now back. */
+  restore(): void;
+}
+
+interface FakeTimer {
+  id: number;
+  at: number;
+  fn: () => void;
+  interval: number | null;
+}
+
+export function installFakeClock(start = 1_000_000): FakeClock {
+  const real = {
+    setTimeout: globalThis.setTimeout,
+    clearTimeout: globalThis.clearTimeout,
+    setInterval: globalThis.setInterval,
+    clearInterval: globalThis.clearInterval,
+    dateNow: Date.now,
+  };
+
+  let now = start;
+  let nextId = 1;
+  const timers = new Map<number, FakeTimer>();
+
+  function schedule(fn: () => void, ms: number | undefined, interval: boolean): number {
+    const id = nextId++;
+    const delay = Math.max(0, ms ?? 0);
+    timers.set(id, { id, at: now + delay, fn, interval: interval ? Math.max(1, delay) : null });
+    return id;
+  }
+
+  function cancel(id: unknown): void {
+    timers.delete(id as number);
+  }
+
+  globalThis.setTimeout = ((fn: () => void, ms?: number) => schedule(fn, ms, false)) as any;
+  globalThis.clearTimeout = cancel as any;
+  globalThis.setInterval = ((fn: () => void, ms?: number) => schedule(fn, ms, true)) as any;
+  globalThis.clearInterval = cancel as any;
+  Date.now = () => now;
+
+  return {
+    now: () => now,
+    advance(ms: number) {
+      const target = now + ms;
+      for (;;) {
+        let due: FakeTimer | null = null;
+        for (const timer of timers.values()) {
+          if (timer.at <= target && (!due || timer.at < due.at)) due = timer;
+        }
+        if (!due) break;
+
+        now = due.at;
+        if (due.interval !== null) {
+          due.at += due.interval;
+        } else {
+          timers.delete(due.id);
+        }
+        due.fn();
+      }
+      now = target;
+    },
+    restore() {
+      globalThis.setTimeout = real.setTimeout;
+      globalThis.clearTimeout = real.clearTimeout;
+      globalThis.setInterval = real.setInterval;
+      globalThis.clearInterval = real.clearInterval;
+      Date.now = real.dateNow;
+    },
+  };
+}
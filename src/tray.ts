@@ -12,7 +12,28 @@ import type { BridgeHandle } from '@/bridge.js';
 // Embed icon as a Bun asset
 import iconPath from '@/static/tray-icon.png' with { type: 'file' };
 
-const configPath = getTrayConfigPath();
+// --config <path> (or --config=<path>) overrides the platform default location
+function parseConfigArg(argv: string[]): string | undefined {
+  for (let i = 0; i < argv.length; i++) {
+    let value: string | undefined;
+    if (argv[i] === '--config') {
+      value = argv[i + 1];
+    } else if (argv[i].startsWith('--config=')) {
+      value = argv[i].slice('--config='.length);
+    } else {
+      continue;
+    }
+    // Quietly falling back to the default file would edit the wrong config
+    if (!value || value.startsWith('--')) {
+      console.error('--config needs a path, e.g. --config ~/pad.yaml');
+      process.exit(1);
+    }
+    return value;
+  }
+  return undefined;
+}
+
+const configPath = getTrayConfigPath(parseConfigArg(process.argv.slice(2)));
 
 // Item ID for the connection status line (used with updateItem for tooltip updates)
 const ITEM_STATUS = 1;
@@ -20,6 +41,8 @@ const ITEM_STATUS = 1;
 let bridge: BridgeHandle | null = null;
 let tray: SysTrayHandle | null = null;
 let settingsHandle: { port: number; stop(): void } | null = null;
+// Outlives the bridge so a settings save doesn't silently resume input
+let inputPaused = false;
 
 async function tryStartBridge() {
   const config = loadConfig(configPath);
@@ -29,8 +52,9 @@ async function tryStartBridge() {
     return null;
   }
   try {
-    const handle = await startBridge(configPath);
+    const handle = await startBridge(configPath, { inputPaused });
     handle.onStatusChange((status) => {
+      inputPaused = status.inputPaused;
       tray?.updateItem(ITEM_STATUS, {
         title: status.connected ? '● Connected' : '○ Disconnected',
       });
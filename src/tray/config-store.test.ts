@@ -0,0 +1,48 @@
+import { describe, test, expect, afterEach } from 'bun:test';
+import { existsSync, mkdtempSync, rmSync } from 'fs';
+import { tmpdir } from 'os';
+import { join, resolve } from 'path';
+import { getTrayConfigPath, resolveTrayConfigPath } from './config-store.js';
+
+const HOME = '/home/pad';
+
+describe('resolveTrayConfigPath', () => {
+  test('uses XDG_CONFIG_HOME on Linux when set', () => {
+    expect(resolveTrayConfigPath(undefined, 'linux', { XDG_CONFIG_HOME: '/xdg' }, HOME))
+      .toBe('/xdg/camel-pad/config.yaml');
+  });
+
+  test('falls back to ~/.config when XDG_CONFIG_HOME is unset or empty', () => {
+    expect(resolveTrayConfigPath(undefined, 'linux', {}, HOME))
+      .toBe('/home/pad/.config/camel-pad/config.yaml');
+    expect(resolveTrayConfigPath(undefined, 'linux', { XDG_CONFIG_HOME: '' }, HOME))
+      .toBe('/home/pad/.config/camel-pad/config.yaml');
+  });
+
+  test('ignores XDG_CONFIG_HOME on macOS', () => {
+    expect(resolveTrayConfigPath(undefined, 'darwin', { XDG_CONFIG_HOME: '/xdg' }, HOME))
+      .toBe('/home/pad/Library/Application Support/camel-pad/config.yaml');
+  });
+
+  test('an explicit path wins and is made absolute', () => {
+    expect(resolveTrayConfigPath('/etc/pad.yaml', 'linux', { XDG_CONFIG_HOME: '/xdg' }, HOME))
+      .toBe('/etc/pad.yaml');
+    expect(resolveTrayConfigPath('pad.yaml', 'darwin', {}, HOME)).toBe(resolve('pad.yaml'));
+  });
+});
+
+describe('getTrayConfigPath', () => {
+  let dir: string | null = null;
+
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = null;
+  });
+
+  test('creates the directory of an explicit path', () => {
+    dir = mkdtempSync(join(tmpdir(), 'camel-pad-'));
+    const path = join(dir, 'nested', 'config.yaml');
+    expect(getTrayConfigPath(path)).toBe(path);
+    expect(existsSync(join(dir, 'nested'))).toBe(true);
+  });
+});
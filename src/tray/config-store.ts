@@ -1,25 +1,40 @@
 import { homedir } from 'os';
-import { join } from 'path';
+import { dirname, join, resolve } from 'path';
 import { mkdirSync } from 'fs';
 
 /**
  * Returns the path to the tray app's config file, creating the directory
- * if it doesn't exist.
+ * if it doesn't exist. An explicit path (from --config) always wins.
+ */
+export function getTrayConfigPath(explicitPath?: string): string {
+  const path = resolveTrayConfigPath(explicitPath, process.platform, process.env);
+  mkdirSync(dirname(path), { recursive: true });
+  return path;
+}
+
+/**
+ * Where the config file lives, without touching the filesystem.
  *
  * macOS: ~/Library/Application Support/camel-pad/config.yaml
  * Windows: %APPDATA%\camel-pad\config.yaml
- * Linux/other: ~/.config/camel-pad/config.yaml
+ * Linux/other: $XDG_CONFIG_HOME/camel-pad/config.yaml (~/.config if unset)
  */
-export function getTrayConfigPath(): string {
+export function resolveTrayConfigPath(
+  explicitPath: string | undefined,
+  platform: NodeJS.Platform,
+  env: NodeJS.ProcessEnv,
+  home = homedir(),
+): string {
+  if (explicitPath) return resolve(explicitPath);
+
   let base: string;
-  if (process.platform === 'darwin') {
-    base = join(homedir(), 'Library', 'Application Support', 'camel-pad');
-  } else if (process.platform === 'win32') {
-    base = join(process.env.APPDATA ?? join(homedir(), 'AppData', 'Roaming'), 'camel-pad');
+  if (platform === 'darwin') {
+    base = join(home, 'Library', 'Application Support', 'camel-pad');
+  } else if (platform === 'win32') {
+    base = join(env.APPDATA ?? join(home, 'AppData', 'Roaming'), 'camel-pad');
   } else {
-    base = join(homedir(), '.config', 'camel-pad');
+    base = join(env.XDG_CONFIG_HOME || join(home, '.config'), 'camel-pad');
   }
 
-  mkdirSync(base, { recursive: true });
   return join(base, 'config.yaml');
 }
@@ -1,6 +1,6 @@
 import { readFileSync, writeFileSync } from 'fs';
 import { stringify } from 'yaml';
-import { loadConfig } from '@/config/loader.js';
+import { loadConfig, validateConfig, withDefaults, CONFIG_VERSION } from '@/config/loader.js';
 import { listPorts } from '@/serial/discovery.js';
 import type { Config } from '@/types.js';
 import type { BridgeHandle } from '@/bridge.js';
@@ -65,6 +65,11 @@ export async function startSettingsServer(
         if (req.method === 'POST') {
           try {
             const body = await req.json() as Partial<Config>;
+            // Refuse what the bridge would refuse to start with
+            const errors = validateConfig(withDefaults(body));
+            if (errors.length > 0) {
+              return new Response(errors.join('; '), { status: 400 });
+            }
             const yaml = buildYaml(body);
             writeFileSync(configPath, yaml, 'utf8');
             onSaved?.();
@@ -85,7 +90,7 @@ export async function startSettingsServer(
       }
 
       if (url.pathname === '/api/status') {
-        const status = bridge ? bridge.getStatus() : { connected: false, portPath: null, pendingCount: 0 };
+        const status = bridge ? bridge.getStatus() : { connected: false, portPath: null, pendingCount: 0, inputPaused: false, firmwareVersion: null };
         return Response.json(status, { headers: corsHeaders });
       }
 
@@ -129,6 +134,16 @@ export async function startSettingsServer(
         return Response.json({ ok }, { headers: corsHeaders });
       }
 
+      if (url.pathname === '/api/input/pause' && req.method === 'POST') {
+        if (!bridge) return Response.json({ ok: false, error: 'bridge not running' }, { headers: corsHeaders });
+        const { paused } = await req.json() as { paused: unknown };
+        if (typeof paused !== 'boolean') {
+          return Response.json({ ok: false, error: 'paused must be true or false' }, { status: 400, headers: corsHeaders });
+        }
+        bridge.setInputPaused(paused);
+        return Response.json({ ok: true }, { headers: corsHeaders });
+      }
+
       if (url.pathname === '/api/close') {
         setTimeout(() => server?.stop(), 200);
         return new Response('ok');
@@ -161,13 +176,17 @@ export async function startSettingsServer(
  * Only writes fields that are set; preserves the simple format.
  */
 function buildYaml(config: Partial<Config>): string {
-  const out: Record<string, any> = {};
+  // Never stamp a newer file with an older version; validateConfig refuses those
+  const out: Record<string, any> = { version: Math.max(config.version ?? CONFIG_VERSION, CONFIG_VERSION) };
 
   if (config.device) {
     out.device = {};
     if (config.device.port) out.device.port = config.device.port;
     if (config.device.vendorId) out.device.vendorId = `0x${config.device.vendorId.toString(16)}`;
     if (config.device.productId) out.device.productId = `0x${config.device.productId.toString(16)}`;
+    if (config.device.serialNumber) out.device.serialNumber = config.device.serialNumber;
+    if (config.device.ignoreButtons?.length) out.device.ignoreButtons = config.device.ignoreButtons;
+    if (config.device.dumpRaw) out.device.dumpRaw = true;
   }
 
   if (config.handedness) out.handedness = config.handedness;
@@ -184,6 +203,9 @@ function buildYaml(config: Partial<Config>): string {
       longPressMs: config.gestures.longPressMs,
       doublePressMs: config.gestures.doublePressMs,
     };
+    if (config.gestures.stuckResetMs) out.gestures.stuckResetMs = config.gestures.stuckResetMs;
+    if (config.gestures.longPress === false) out.gestures.longPress = false;
+    if (config.gestures.doublePress === false) out.gestures.doublePress = false;
   }
 
   if (config.defaults) {
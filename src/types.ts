@@ -1,10 +1,14 @@
 // Shared types for camel-pad
 
 export interface Config {
+  version: number;
   device: {
     port?: string;
     vendorId?: number;
     productId?: number;
+    serialNumber?: string; // Picks one of several devices sharing vendor/product IDs
+    ignoreButtons?: number[]; // Logical key indices (as in keys.keyN) whose events are dropped
+    dumpRaw?: boolean; // Log every chunk read from the port as hex, for firmware debugging
   };
   server: {
     port: number;
@@ -13,6 +17,9 @@ export interface Config {
   gestures: {
     longPressMs: number;
     doublePressMs: number;
+    stuckResetMs: number;
+    longPress: boolean;
+    doublePress: boolean;
   };
   keys: Record<string, KeyMapping>;
   defaults: {
@@ -30,6 +37,8 @@ export interface KeyMapping {
 export interface ActionMapping {
   action: string;
   label: string;
+  enabled?: boolean; // false skips the mapping without deleting it (default true)
+  cooldownMs?: number; // Minimum interval between consecutive firings
 }
 
 // WebSocket message types
@@ -68,6 +77,8 @@ export const MSG_CLEAR = 0x05;
 export const MSG_SET_LABELS = 0x06;
 export const MSG_HEARTBEAT = 0x07;
 export const MSG_PING      = 0x08; // Host→Device: keepalive (no payload)
+export const MSG_GET_VERSION = 0x09; // Host→Device: request firmware version (no payload)
+export const MSG_VERSION   = 0x0A; // Device→Host: firmware version (UTF-8 payload)
 
 // Monitor log entry
 export interface LogEntry {
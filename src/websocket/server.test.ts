@@ -0,0 +1,66 @@
+import { describe, test, expect, beforeEach, afterEach } from 'bun:test';
+import { NotificationServer } from './server.js';
+import { withDefaults } from '../config/loader.js';
+import { installFakeClock, type FakeClock } from '../testing/fake-clock.js';
+import type { KeyMapping, ResponseMessage } from '../types.js';
+
+let clock: FakeClock;
+let server: NotificationServer;
+let responses: ResponseMessage[];
+
+function setup(keys: Record<string, KeyMapping>) {
+  server = new NotificationServer(withDefaults({ keys }));
+  responses = [];
+}
+
+/** Queue notifications as if the plugin had sent them; the server is never started. */
+function notify(...ids: string[]) {
+  const ws = { send: (data: string) => responses.push(JSON.parse(data)) };
+  for (const id of ids) {
+    (server as any).handleMessage(ws, { type: 'notification', id, text: `Allow ${id}?` });
+  }
+}
+
+beforeEach(() => {
+  clock = installFakeClock();
+});
+
+afterEach(() => {
+  server.stop();
+  clock.restore();
+});
+
+describe('enabled', () => {
+  test('a disabled press is skipped while its doublePress still answers', () => {
+    setup({
+      key0: {
+        press: { action: 'approve', label: 'Yes', enabled: false },
+        doublePress: { action: 'approve-all', label: 'Always' },
+      },
+    });
+    notify('a');
+
+    expect(server.handleGesture('key0', 'press')).toBe(false);
+    expect(server.hasPending()).toBe(true);
+
+    expect(server.handleGesture('key0', 'doublePress')).toBe(true);
+    expect(responses).toEqual([{ type: 'response', id: 'a', action: 'approve-all', label: 'Always' }]);
+  });
+});
+
+describe('cooldownMs', () => {
+  test('a repeat within the cooldown is dropped, a later one answers', () => {
+    setup({ key0: { press: { action: 'approve', label: 'Yes', cooldownMs: 1000 } } });
+    notify('a', 'b', 'c');
+
+    expect(server.handleGesture('key0', 'press')).toBe(true);
+    clock.advance(200);
+    expect(server.handleGesture('key0', 'press')).toBe(false);
+    expect(responses.map(r => r.id)).toEqual(['a']);
+
+    clock.advance(1000);
+    expect(server.handleGesture('key0', 'press')).toBe(true);
+    expect(responses.map(r => r.id)).toEqual(['a', 'b']);
+    expect(server.currentNotification()?.id).toBe('c');
+  });
+});
@@ -14,6 +14,7 @@ export class NotificationServer extends EventEmitter {
   private config: Config;
   private pending: Map<string, PendingNotification> = new Map();
   private notificationQueue: string[] = []; // Order of pending notifications
+  private lastFired: Map<string, number> = new Map(); // Last firing time per button.gesture
 
   constructor(config: Config) {
     super();
@@ -133,6 +134,22 @@ export class NotificationServer extends EventEmitter {
       console.warn(`No mapping for gesture: ${buttonId}.${gesture}`);
       return false;
     }
+    if (actionMapping.enabled === false) {
+      console.warn(`Mapping disabled: ${buttonId}.${gesture}`);
+      return false;
+    }
+
+    // Drop repeats of the same mapping within its cooldown
+    const mappingKey = `${buttonId}.${gesture}`;
+    const now = Date.now();
+    if (actionMapping.cooldownMs) {
+      const last = this.lastFired.get(mappingKey);
+      if (last !== undefined && now - last < actionMapping.cooldownMs) {
+        console.log(`Cooldown active for ${mappingKey}, ignoring`);
+        return false;
+      }
+    }
+    this.lastFired.set(mappingKey, now);
 
     // Clear timeout and remove from queue
     clearTimeout(pending.timeoutHandle);
@@ -173,6 +190,13 @@ export class NotificationServer extends EventEmitter {
     return this.notificationQueue.length > 0;
   }
 
+  /** The notification currently awaiting a response (oldest first), if any. */
+  currentNotification(): NotificationMessage | null {
+    const pending = this.pending.get(this.notificationQueue[0]);
+    if (!pending) return null;
+    return { type: 'notification', id: pending.id, text: pending.text, category: pending.category };
+  }
+
   stop(): void {
     // Clear all pending timeouts
     for (const pending of this.pending.values()) {
@@ -180,6 +204,7 @@ export class NotificationServer extends EventEmitter {
     }
     this.pending.clear();
     this.notificationQueue = [];
+    this.lastFired.clear();
 
     if (this.wss) {
       this.wss.close();